@@ -0,0 +1,115 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/tools"
+)
+
+// maxAgentIterations bounds the ReAct-style tool loop so a model that keeps
+// requesting tool calls can't keep a request open forever.
+const maxAgentIterations = 8
+
+// runAgentLoop drives a function-calling model through a ReAct-style loop:
+// the model either returns a final answer or a tool call, the tool result
+// is appended to the conversation, and the model is re-invoked until it
+// answers or maxAgentIterations is hit. Each tool call is both streamed to
+// the client as an SSE "tool_call" event and recorded as a MessageStep so
+// the UI can render the reasoning trace after the fact; the final answer is
+// written to writer and recorded as a MessageStep too.
+func (c *ApiController) runAgentLoop(ctx context.Context, owner string, provider object.FunctionCallingModelProvider, question string, history []*object.RawMessage, prompt string, knowledge []*object.Knowledge, writer io.Writer) ([]object.MessageStep, error) {
+	toolConfigs, err := object.GetTools(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	enabledTools := map[string]bool{}
+	for _, toolConfig := range toolConfigs {
+		if toolConfig.Enabled {
+			enabledTools[toolConfig.Name] = true
+		}
+	}
+
+	toolSchemas := make([]map[string]interface{}, 0)
+	for _, tool := range tools.List() {
+		if !enabledTools[tool.Name()] {
+			continue
+		}
+		toolSchemas = append(toolSchemas, tool.Schema())
+	}
+
+	steps := make([]object.MessageStep, 0)
+	currentHistory := history
+
+	for i := 0; i < maxAgentIterations; i++ {
+		toolCall, answer, err := provider.QueryToolCall(ctx, question, currentHistory, prompt, knowledge, toolSchemas)
+		if err != nil {
+			return steps, err
+		}
+
+		if toolCall == nil {
+			_, err = writer.Write([]byte(answer))
+			if err != nil {
+				return steps, err
+			}
+			steps = append(steps, object.MessageStep{Index: i, Text: answer})
+			return steps, nil
+		}
+
+		err = c.emitToolCallEvent(toolCall)
+		if err != nil {
+			return steps, err
+		}
+
+		var output string
+		if !enabledTools[toolCall.Name] {
+			output = fmt.Sprintf("error: tool %s is not enabled for this owner", toolCall.Name)
+		} else {
+			output, err = tools.Call(ctx, owner, toolCall.Name, toolCall.Input)
+			if err != nil {
+				output = fmt.Sprintf("error: %s", err.Error())
+			}
+		}
+
+		steps = append(steps, object.MessageStep{Index: i, ToolName: toolCall.Name, Input: toolCall.Input, Output: output})
+
+		currentHistory = append(currentHistory,
+			&object.RawMessage{Role: "assistant", Text: fmt.Sprintf("tool_call: %s(%s)", toolCall.Name, toolCall.Input)},
+			&object.RawMessage{Role: "tool", Text: output},
+		)
+	}
+
+	return steps, fmt.Errorf("the agent loop exceeded %d iterations without a final answer", maxAgentIterations)
+}
+
+func (c *ApiController) emitToolCallEvent(toolCall *object.ToolCall) error {
+	jsonData, err := ConvertMessageDataToJSON(fmt.Sprintf("%s(%s)", toolCall.Name, toolCall.Input))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Ctx.ResponseWriter.Write([]byte(fmt.Sprintf("event: tool_call\ndata: %s\n\n", jsonData)))
+	if err != nil {
+		return err
+	}
+
+	c.Ctx.ResponseWriter.Flush()
+	return nil
+}
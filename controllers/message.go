@@ -17,11 +17,23 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/object/multimodal"
+	"github.com/casibase/casibase/ratelimit"
+	"github.com/casibase/casibase/streamstore"
 	"github.com/casibase/casibase/util"
 )
 
+func toMultimodalParts(parts []multimodal.Part) []object.MultimodalPart {
+	result := make([]object.MultimodalPart, 0, len(parts))
+	for _, part := range parts {
+		result = append(result, object.MultimodalPart{Type: part.Type, Text: part.Text, Url: part.Url})
+	}
+	return result
+}
+
 func (c *ApiController) GetGlobalMessages() {
 	messages, err := object.GetGlobalMessages()
 	if err != nil {
@@ -35,6 +47,7 @@ func (c *ApiController) GetGlobalMessages() {
 func (c *ApiController) GetMessages() {
 	owner := c.Input().Get("owner")
 	chat := c.Input().Get("chat")
+	branch := c.Input().Get("branch")
 
 	if chat == "" {
 		messages, err := object.GetMessages(owner)
@@ -46,6 +59,16 @@ func (c *ApiController) GetMessages() {
 		return
 	}
 
+	if branch != "" {
+		messages, err := object.GetActiveChatMessages(chat)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		c.ResponseOk(messages)
+		return
+	}
+
 	messages, err := object.GetChatMessages(chat)
 	if err != nil {
 		c.ResponseError(err.Error())
@@ -90,6 +113,52 @@ func (c *ApiController) GetMessageAnswer() {
 		return
 	}
 
+	from := 0
+	if fromStr := c.Input().Get("from"); fromStr != "" {
+		from, err = strconv.Atoi(fromStr)
+		if err != nil {
+			c.ResponseErrorStream(err.Error())
+			return
+		}
+	}
+
+	buffered, _, err := streamstore.Default().ReplayFrom(id, from)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+	if len(buffered) > 0 {
+		err = c.writeMessageEvent(string(buffered))
+		if err != nil {
+			c.ResponseErrorStream(err.Error())
+			return
+		}
+	}
+
+	done, err := streamstore.Default().Done(id)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+	if done {
+		c.writeEndEvent()
+		return
+	}
+
+	viewerCh, isGenerator, leaveBroadcast := messageBroadcaster.Join(id)
+	defer leaveBroadcast()
+
+	if !isGenerator {
+		for chunk := range viewerCh {
+			err = c.writeMessageEvent(string(chunk))
+			if err != nil {
+				return
+			}
+		}
+		c.writeEndEvent()
+		return
+	}
+
 	chatId := util.GetIdFromOwnerAndName(message.Owner, message.Chat)
 	chat, err := object.GetChat(chatId)
 	if err != nil {
@@ -118,8 +187,9 @@ func (c *ApiController) GetMessageAnswer() {
 	}
 
 	question := store.Welcome
+	var questionMessage *object.Message
 	if message.ReplyTo != "Welcome" {
-		questionMessage, err := object.GetMessage(message.ReplyTo)
+		questionMessage, err = object.GetMessage(message.ReplyTo)
 		if err != nil {
 			c.ResponseErrorStream(err.Error())
 			return
@@ -137,20 +207,56 @@ func (c *ApiController) GetMessageAnswer() {
 		return
 	}
 
-	_, ok := c.CheckSignedIn()
-	if !ok {
-		var count int
-		count, err = object.GetNearMessageCount(message.User, store.LimitMinutes)
+	rateLimitKey := rateLimitKeyForMessage(store.Name, message.User, message.Organization, chat.User2)
+
+	// Only anonymous callers are subject to the requests/minute bucket, same
+	// as the original GetNearMessageCount/store.Frequency check this
+	// replaced. Concurrent-stream and daily-token budgets apply to every
+	// caller, signed in or not.
+	_, signedIn := c.CheckSignedIn()
+	if !signedIn {
+		quota, err := messageLimiter.Allow(rateLimitKey, ratelimit.BucketRequestsPerMinute, 1)
 		if err != nil {
 			c.ResponseErrorStream(err.Error())
 			return
 		}
-		if count > store.Frequency {
+		setRateLimitHeaders(c, quota)
+		if !quota.Allowed {
 			c.ResponseErrorStream("You have queried too many times, please wait for a while")
 			return
 		}
 	}
 
+	streamQuota, err := messageLimiter.Allow(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+	if !streamQuota.Allowed {
+		// Allow already charged this attempt against the bucket even though
+		// it's being rejected (MemoryLimiter deducts unconditionally); give
+		// that unit back immediately since no stream is actually starting.
+		messageLimiter.Release(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+		c.ResponseErrorStream("You have too many concurrent streams open, please wait for one to finish")
+		return
+	}
+	// Hold this slot for the lifetime of the stream rather than letting it
+	// refill on BucketConcurrentStreams's per-minute schedule, so a client
+	// that opens one long-running stream can't open unbounded others once
+	// the first minute's tokens refill - that's "concurrent streams", not
+	// "stream starts per minute".
+	defer messageLimiter.Release(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+
+	tokenQuota, err := messageLimiter.Peek(rateLimitKey, ratelimit.BucketTokensPerDay)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+	if !tokenQuota.Allowed {
+		c.ResponseErrorStream("You have reached your daily token budget, please try again tomorrow")
+		return
+	}
+
 	_, modelProviderObj, err := getModelProviderFromContext("admin", chat.User2)
 	if err != nil {
 		c.ResponseErrorStream(err.Error())
@@ -167,14 +273,17 @@ func (c *ApiController) GetMessageAnswer() {
 	c.Ctx.ResponseWriter.Header().Set("Cache-Control", "no-cache")
 	c.Ctx.ResponseWriter.Header().Set("Connection", "keep-alive")
 
-	knowledge, vectorScores, err := object.GetNearestKnowledge(embeddingProvider, embeddingProviderObj, "admin", question)
+	ctx := c.Ctx.Request.Context()
+
+	knowledge, vectorScores, err := object.GetNearestKnowledge(ctx, embeddingProvider, embeddingProviderObj, "admin", question)
 	if err != nil && err.Error() != "no knowledge vectors found" {
 		c.ResponseErrorStream(err.Error())
 		return
 	}
 
 	writer := &RefinedWriter{*c.Ctx.ResponseWriter, *NewCleaner(6), []byte{}}
-	history, err := object.GetRecentRawMessages(chat.Name, store.MemoryLimit)
+	streamingWriter := newStreamTeeWriter(id, writer)
+	history, err := object.GetActiveRecentRawMessages(chat.Name, store.MemoryLimit)
 	if err != nil {
 		c.ResponseErrorStream(err.Error())
 		return
@@ -189,8 +298,18 @@ func (c *ApiController) GetMessageAnswer() {
 	// fmt.Printf("Refined Question: [%s]\n", realQuestion)
 	fmt.Printf("Answer: [")
 
-	err = modelProviderObj.QueryText(question, writer, history, store.Prompt, knowledge)
+	var steps []object.MessageStep
+	if questionMessage != nil && len(questionMessage.MultimodalParts) > 0 {
+		err = modelProviderObj.QueryMultimodal(ctx, question, questionMessage.MultimodalParts, streamingWriter, history, store.Prompt, knowledge)
+	} else if functionCallingProvider, ok := modelProviderObj.(object.FunctionCallingModelProvider); ok {
+		steps, err = c.runAgentLoop(ctx, "admin", functionCallingProvider, question, history, store.Prompt, knowledge, streamingWriter)
+		message.Steps = steps
+	} else {
+		err = modelProviderObj.QueryText(ctx, question, streamingWriter, history, store.Prompt, knowledge)
+	}
 	if err != nil {
+		streamstore.Default().Finish(id)
+		messageBroadcaster.Close(id)
 		c.ResponseErrorStream(err.Error())
 		return
 	}
@@ -213,15 +332,26 @@ func (c *ApiController) GetMessageAnswer() {
 
 	fmt.Printf("]\n")
 
-	event := fmt.Sprintf("event: end\ndata: %s\n\n", "end")
-	_, err = c.Ctx.ResponseWriter.Write([]byte(event))
+	err = streamstore.Default().Finish(id)
 	if err != nil {
 		c.ResponseErrorStream(err.Error())
 		return
 	}
+	messageBroadcaster.Close(id)
+
+	c.writeEndEvent()
 
 	answer := writer.String()
 
+	// Roughly four characters per token; exact tokenization depends on the
+	// model provider, which doesn't report usage through the io.Writer
+	// interface QueryText streams through.
+	_, err = messageLimiter.Allow(rateLimitKey, ratelimit.BucketTokensPerDay, (len(question)+len(answer))/4)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+
 	message.Text = answer
 	message.VectorScores = vectorScores
 	_, err = object.UpdateMessage(message.GetId(), message)
@@ -229,6 +359,42 @@ func (c *ApiController) GetMessageAnswer() {
 		c.ResponseErrorStream(err.Error())
 		return
 	}
+
+	err = streamstore.Default().Discard(id)
+	if err != nil {
+		c.ResponseErrorStream(err.Error())
+		return
+	}
+}
+
+// writeEndEvent writes the terminal SSE "end" frame shared by the live
+// generation path and the replay-only paths (already-finished stream,
+// viewer whose broadcast channel just closed).
+func (c *ApiController) writeEndEvent() {
+	event := fmt.Sprintf("event: end\ndata: %s\n\n", "end")
+	c.Ctx.ResponseWriter.Write([]byte(event))
+}
+
+// writeMessageEvent frames text as an "event: message" SSE frame, the same
+// way RefinedWriter does for the generating viewer. streamstore.ReplayFrom
+// and the broadcaster's viewerCh both hand back the raw, pre-RefinedWriter
+// bytes tapped by streamTeeWriter, so a replayed or broadcast chunk needs
+// this same framing before it goes out - otherwise a second viewer or a
+// reconnecting client gets an unframed byte stream an EventSource can't
+// parse as message events.
+func (c *ApiController) writeMessageEvent(text string) error {
+	jsonData, err := ConvertMessageDataToJSON(text)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Ctx.ResponseWriter.Write([]byte(fmt.Sprintf("event: message\ndata: %s\n\n", jsonData)))
+	if err != nil {
+		return err
+	}
+
+	c.Ctx.ResponseWriter.Flush()
+	return nil
 }
 
 func (c *ApiController) UpdateMessage() {
@@ -273,6 +439,30 @@ func (c *ApiController) AddMessage() {
 		}
 	}
 
+	provider := ""
+	if chat != nil {
+		provider = chat.User2
+	}
+	rateLimitKey := rateLimitKeyForMessage("", message.User, message.Organization, provider)
+
+	// Only anonymous callers are subject to the requests/minute bucket here
+	// too, same exemption GetMessageAnswer applies - AddMessage never had
+	// any rate limit in baseline, and without this every signed-in user
+	// would be throttled to 20 posts/minute.
+	_, signedIn := c.CheckSignedIn()
+	if !signedIn {
+		quota, err := messageLimiter.Allow(rateLimitKey, ratelimit.BucketRequestsPerMinute, 1)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		setRateLimitHeaders(c, quota)
+		if !quota.Allowed {
+			c.ResponseError("You have queried too many times, please wait for a while")
+			return
+		}
+	}
+
 	host := c.Ctx.Request.Host
 	origin := getOriginFromHost(host)
 	err = object.RefineMessageImages(&message, origin)
@@ -281,6 +471,33 @@ func (c *ApiController) AddMessage() {
 		return
 	}
 
+	if len(message.Attachments) > 0 {
+		visionCapable := false
+		if chat != nil && chat.Type == "AI" {
+			_, modelProviderObj, err := getModelProviderFromContext("admin", chat.User2)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+			if visionProvider, ok := modelProviderObj.(object.VisionCapableModelProvider); ok {
+				visionCapable = visionProvider.SupportsVision()
+			}
+		}
+
+		transcript, parts, err := multimodal.ProcessAttachments(c.Ctx.Request.Context(), message.Attachments, visionCapable)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if transcript != "" {
+			if message.Text != "" {
+				message.Text += "\n"
+			}
+			message.Text += transcript
+		}
+		message.MultimodalParts = toMultimodalParts(parts)
+	}
+
 	message.CreatedTime = util.GetCurrentTimeWithMilli()
 
 	success, err := object.AddMessage(&message)
@@ -299,6 +516,7 @@ func (c *ApiController) AddMessage() {
 				User:         message.User,
 				Chat:         message.Chat,
 				ReplyTo:      message.GetId(),
+				ParentId:     message.GetId(),
 				Author:       "AI",
 				Text:         "",
 				VectorScores: []object.VectorScore{},
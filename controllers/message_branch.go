@@ -0,0 +1,197 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/util"
+)
+
+// RegenerateMessage creates a new AI child under the same parent as the
+// message identified by "id", so the original answer stays in history as a
+// sibling instead of being overwritten.
+func (c *ApiController) RegenerateMessage() {
+	id := c.Input().Get("id")
+
+	message, err := object.GetMessage(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if message == nil {
+		c.ResponseError(fmt.Sprintf("The message: %s is not found", id))
+		return
+	}
+	if message.Author != "AI" {
+		c.ResponseError("Only an AI message can be regenerated")
+		return
+	}
+
+	siblingCount, err := object.GetSiblingCount(message.ParentId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	newMessage := &object.Message{
+		Owner:        message.Owner,
+		Name:         fmt.Sprintf("message_%s", util.GetRandomName()),
+		CreatedTime:  util.GetCurrentTimeWithMilli(),
+		User:         message.User,
+		Chat:         message.Chat,
+		ReplyTo:      message.ReplyTo,
+		ParentId:     message.ParentId,
+		SiblingIndex: siblingCount,
+		Author:       "AI",
+		Text:         "",
+		VectorScores: []object.VectorScore{},
+	}
+
+	success, err := object.AddMessage(newMessage)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if success {
+		err = object.SetActiveChild(message.ParentId, newMessage.GetId())
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+
+	c.ResponseOk(newMessage)
+}
+
+// EditMessage forks a new branch off message "id" with the given text,
+// instead of mutating the original message in place, so the earlier answers
+// that were generated in response to it remain reachable on their own
+// branch.
+func (c *ApiController) EditMessage() {
+	id := c.Input().Get("id")
+	text := c.Input().Get("text")
+
+	message, err := object.GetMessage(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if message == nil {
+		c.ResponseError(fmt.Sprintf("The message: %s is not found", id))
+		return
+	}
+
+	siblingCount, err := object.GetSiblingCount(message.ParentId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	newMessage := &object.Message{
+		Owner:        message.Owner,
+		Name:         fmt.Sprintf("message_%s", util.GetRandomName()),
+		CreatedTime:  util.GetCurrentTimeWithMilli(),
+		User:         message.User,
+		Chat:         message.Chat,
+		ParentId:     message.ParentId,
+		SiblingIndex: siblingCount,
+		Author:       message.Author,
+		Text:         text,
+		VectorScores: []object.VectorScore{},
+	}
+
+	success, err := object.AddMessage(newMessage)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if success {
+		err = object.SetActiveChild(message.ParentId, newMessage.GetId())
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		// Editing a question forks a new branch with no AI answer of its
+		// own yet; spawn the same empty placeholder AddMessage creates for
+		// a freshly posted question, so the edited branch has somewhere
+		// for GetMessageAnswer to write the regenerated answer instead of
+		// requiring the frontend to make a second, unlinked AddMessage call.
+		if newMessage.Author != "AI" {
+			chatId := util.GetIdFromOwnerAndName(newMessage.Owner, newMessage.Chat)
+			chat, err := object.GetChat(chatId)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+			if chat != nil && chat.Type == "AI" {
+				answerMessage := &object.Message{
+					Owner:        newMessage.Owner,
+					Name:         fmt.Sprintf("message_%s", util.GetRandomName()),
+					CreatedTime:  util.GetCurrentTimeEx(newMessage.CreatedTime),
+					User:         newMessage.User,
+					Chat:         newMessage.Chat,
+					ReplyTo:      newMessage.GetId(),
+					ParentId:     newMessage.GetId(),
+					Author:       "AI",
+					Text:         "",
+					VectorScores: []object.VectorScore{},
+				}
+				_, err = object.AddMessage(answerMessage)
+				if err != nil {
+					c.ResponseError(err.Error())
+					return
+				}
+			}
+		}
+	}
+
+	c.ResponseOk(newMessage)
+}
+
+// SwitchBranch marks messageId as the active child of its parent within
+// chatId, so GetActiveChatMessages walks through it instead of one of its
+// siblings.
+func (c *ApiController) SwitchBranch() {
+	chatId := c.Input().Get("chatId")
+	messageId := c.Input().Get("messageId")
+
+	message, err := object.GetMessage(messageId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if message == nil {
+		c.ResponseError(fmt.Sprintf("The message: %s is not found", messageId))
+		return
+	}
+
+	if util.GetIdFromOwnerAndName(message.Owner, message.Chat) != chatId {
+		c.ResponseError("The message does not belong to the given chat")
+		return
+	}
+
+	err = object.SetActiveChild(message.ParentId, messageId)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -0,0 +1,284 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/ratelimit"
+	"github.com/casibase/casibase/util"
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// wsFrame is the envelope used for every message sent over the chat
+// WebSocket in both directions. Type is one of: "question", "token",
+// "knowledge_hit", "end", "error" (server -> client) or "cancel"
+// (client -> server).
+type wsFrame struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+func writeWsFrame(conn *websocket.Conn, frameType string, data string) error {
+	return conn.WriteJSON(&wsFrame{Type: frameType, Data: data})
+}
+
+// GetMessageAnswerWS upgrades the connection to a WebSocket and streams the
+// AI answer for the message identified by the "id" query param, the same way
+// GetMessageAnswer does over SSE. Unlike the SSE endpoint, the socket stays
+// open for the lifetime of the generation and accepts an inbound "cancel"
+// frame at any time, which aborts the in-flight QueryText call via ctx.
+func (c *ApiController) GetMessageAnswerWS() {
+	id := c.Input().Get("id")
+
+	conn, err := wsUpgrader.Upgrade(c.Ctx.ResponseWriter, c.Ctx.Request, nil)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	defer conn.Close()
+
+	message, err := object.GetMessage(id)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	if message == nil {
+		writeWsFrame(conn, "error", fmt.Sprintf("The message: %s is not found", id))
+		return
+	}
+	if message.Author != "AI" || message.ReplyTo == "" || message.Text != "" {
+		writeWsFrame(conn, "error", "The message is invalid")
+		return
+	}
+
+	chatId := util.GetIdFromOwnerAndName(message.Owner, message.Chat)
+	chat, err := object.GetChat(chatId)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	if chat.Type != "AI" {
+		writeWsFrame(conn, "error", "The chat type must be \"AI\"")
+		return
+	}
+
+	store, err := object.GetDefaultStore("admin")
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	if store == nil {
+		writeWsFrame(conn, "error", "The default store is not found")
+		return
+	}
+
+	question := store.Welcome
+	if message.ReplyTo != "Welcome" {
+		questionMessage, err := object.GetMessage(message.ReplyTo)
+		if err != nil {
+			writeWsFrame(conn, "error", err.Error())
+			return
+		}
+		if questionMessage == nil {
+			writeWsFrame(conn, "error", fmt.Sprintf("The message: %s is not found", id))
+			return
+		}
+		question = questionMessage.Text
+	}
+	if question == "" {
+		writeWsFrame(conn, "error", "The question should not be empty")
+		return
+	}
+
+	// Same quotas GetMessageAnswer enforces over SSE - without this, an
+	// abusive client could simply switch to the WebSocket endpoint to
+	// bypass every per-user rate limit and quota the ratelimit package
+	// adds. Response headers can't be set here since the connection has
+	// already been upgraded, so setRateLimitHeaders doesn't apply.
+	rateLimitKey := rateLimitKeyForMessage(store.Name, message.User, message.Organization, chat.User2)
+
+	_, signedIn := c.CheckSignedIn()
+	if !signedIn {
+		quota, err := messageLimiter.Allow(rateLimitKey, ratelimit.BucketRequestsPerMinute, 1)
+		if err != nil {
+			writeWsFrame(conn, "error", err.Error())
+			return
+		}
+		if !quota.Allowed {
+			writeWsFrame(conn, "error", "You have queried too many times, please wait for a while")
+			return
+		}
+	}
+
+	streamQuota, err := messageLimiter.Allow(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	if !streamQuota.Allowed {
+		// Allow already charged this attempt against the bucket even though
+		// it's being rejected (MemoryLimiter deducts unconditionally); give
+		// that unit back immediately since no stream is actually starting.
+		messageLimiter.Release(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+		writeWsFrame(conn, "error", "You have too many concurrent streams open, please wait for one to finish")
+		return
+	}
+	// Hold this slot for the lifetime of the socket, the same way
+	// GetMessageAnswer does over SSE, so the count reflects streams
+	// actually in flight rather than stream starts per minute.
+	defer messageLimiter.Release(rateLimitKey, ratelimit.BucketConcurrentStreams, 1)
+
+	tokenQuota, err := messageLimiter.Peek(rateLimitKey, ratelimit.BucketTokensPerDay)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	if !tokenQuota.Allowed {
+		writeWsFrame(conn, "error", "You have reached your daily token budget, please try again tomorrow")
+		return
+	}
+
+	writeWsFrame(conn, "question", question)
+
+	_, modelProviderObj, err := getModelProviderFromContext("admin", chat.User2)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+
+	embeddingProvider, embeddingProviderObj, err := getEmbeddingProviderFromContext("admin", chat.User2)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A client can abort the in-flight generation at any point by sending a
+	// "cancel" frame. A read error (e.g. the client closing the socket)
+	// cancels too; any other inbound frame is ignored.
+	go func() {
+		for {
+			var frame wsFrame
+			if err := conn.ReadJSON(&frame); err != nil {
+				cancel()
+				return
+			}
+			if frame.Type == "cancel" {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	knowledge, vectorScores, err := object.GetNearestKnowledge(ctx, embeddingProvider, embeddingProviderObj, "admin", question)
+	if err != nil && err.Error() != "no knowledge vectors found" {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+	for _, k := range knowledge {
+		writeWsFrame(conn, "knowledge_hit", k.Text)
+	}
+
+	history, err := object.GetActiveRecentRawMessages(chat.Name, store.MemoryLimit)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+
+	writer := newWsTokenWriter(ctx, conn)
+	err = modelProviderObj.QueryText(ctx, question, writer, history, store.Prompt, knowledge)
+	if err != nil {
+		if ctx.Err() != nil {
+			writeWsFrame(conn, "end", "cancelled")
+			// A cancelled generation already streamed every token frame up
+			// to this point to the client; persist that partial answer
+			// instead of discarding it, the same way a completed answer is
+			// always saved below.
+			message.Text = writer.String()
+			message.VectorScores = vectorScores
+			_, updateErr := object.UpdateMessage(message.GetId(), message)
+			if updateErr != nil {
+				writeWsFrame(conn, "error", updateErr.Error())
+			}
+			return
+		}
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+
+	writeWsFrame(conn, "end", "")
+
+	answer := writer.String()
+	message.Text = answer
+	message.VectorScores = vectorScores
+	_, err = object.UpdateMessage(message.GetId(), message)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+
+	// Roughly four characters per token, the same heuristic
+	// GetMessageAnswer uses; QueryText doesn't report usage through the
+	// io.Writer interface it streams through.
+	_, err = messageLimiter.Allow(rateLimitKey, ratelimit.BucketTokensPerDay, (len(question)+len(answer))/4)
+	if err != nil {
+		writeWsFrame(conn, "error", err.Error())
+		return
+	}
+}
+
+// wsTokenWriter implements io.Writer by forwarding every write as a "token"
+// frame over the WebSocket, while also buffering the full answer so it can
+// be persisted once the stream ends. It carries ctx so a cancellation
+// lands here too, not just at the model provider's next ctx.Done() check:
+// once cancelled, further writes are rejected instead of still being
+// flushed to a socket the client may already be tearing down.
+type wsTokenWriter struct {
+	ctx  context.Context
+	conn *websocket.Conn
+	buf  []byte
+}
+
+func newWsTokenWriter(ctx context.Context, conn *websocket.Conn) *wsTokenWriter {
+	return &wsTokenWriter{ctx: ctx, conn: conn}
+}
+
+func (w *wsTokenWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	w.buf = append(w.buf, p...)
+	if err := writeWsFrame(w.conn, "token", string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *wsTokenWriter) String() string {
+	return string(w.buf)
+}
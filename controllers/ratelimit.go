@@ -0,0 +1,114 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/casibase/casibase/ratelimit"
+)
+
+// bucketFromRequest parses the "bucket" query param into a ratelimit.Bucket,
+// defaulting to BucketRequestsPerMinute for compatibility with callers that
+// predate the bucket param. An unrecognized value is rejected rather than
+// silently falling back, since GetQuota/ResetQuota are admin tools and a
+// typo'd bucket name should fail loudly instead of quietly inspecting or
+// resetting the wrong quota.
+func bucketFromRequest(c *ApiController) (ratelimit.Bucket, error) {
+	name := c.Input().Get("bucket")
+	if name == "" {
+		return ratelimit.BucketRequestsPerMinute, nil
+	}
+
+	switch ratelimit.Bucket(name) {
+	case ratelimit.BucketRequestsPerMinute, ratelimit.BucketTokensPerDay, ratelimit.BucketConcurrentStreams:
+		return ratelimit.Bucket(name), nil
+	default:
+		return "", fmt.Errorf("unknown bucket: %s", name)
+	}
+}
+
+// messageLimiter backs the per-request quota checks in AddMessage and
+// GetMessageAnswer. It is a package-level var (rather than threaded through
+// every call) because every ApiController request handler is built fresh
+// per-request by beego, same as the rest of the provider lookups in this
+// package.
+var messageLimiter ratelimit.Limiter = ratelimit.NewMemoryLimiter([]ratelimit.Limit{
+	{Bucket: ratelimit.BucketRequestsPerMinute, Max: 20, Window: time.Minute},
+	{Bucket: ratelimit.BucketConcurrentStreams, Max: 2, Window: time.Minute},
+	{Bucket: ratelimit.BucketTokensPerDay, Max: 200000, Window: 24 * time.Hour},
+})
+
+func rateLimitKeyForMessage(storeName string, user string, organization string, provider string) ratelimit.Key {
+	return ratelimit.Key{Store: storeName, User: user, Organization: organization, Provider: provider}
+}
+
+// setRateLimitHeaders exposes the outcome of a quota check to the client so
+// it can display a cooldown timer instead of silently retrying.
+func setRateLimitHeaders(c *ApiController, quota ratelimit.Quota) {
+	c.Ctx.ResponseWriter.Header().Set("X-RateLimit-Remaining", strconv.Itoa(quota.Remaining))
+	c.Ctx.ResponseWriter.Header().Set("X-RateLimit-Reset", strconv.FormatInt(quota.ResetAt.Unix(), 10))
+}
+
+// GetQuota lets an admin inspect the current quota usage for a given key.
+func (c *ApiController) GetQuota() {
+	if !c.IsAdmin() {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	bucket, err := bucketFromRequest(c)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	key := rateLimitKeyForMessage(c.Input().Get("store"), c.Input().Get("user"), c.Input().Get("organization"), c.Input().Get("provider"))
+
+	quota, err := messageLimiter.Peek(key, bucket)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(quota)
+}
+
+// ResetQuota lets an admin clear a throttled key, e.g. after manually
+// raising a user's limit.
+func (c *ApiController) ResetQuota() {
+	if !c.IsAdmin() {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	bucket, err := bucketFromRequest(c)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	key := rateLimitKeyForMessage(c.Input().Get("store"), c.Input().Get("user"), c.Input().Get("organization"), c.Input().Get("provider"))
+
+	err = messageLimiter.Reset(key, bucket)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(fmt.Sprintf("Quota for %s has been reset", key.String()))
+}
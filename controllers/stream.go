@@ -0,0 +1,50 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"io"
+
+	"github.com/casibase/casibase/streamstore"
+)
+
+// messageBroadcaster fans a single in-flight QueryText call out to every
+// viewer of the same message, so a refresh or a second device opening the
+// same chat attaches to the live generation instead of starting a second
+// one.
+var messageBroadcaster = streamstore.NewBroadcaster()
+
+// streamTeeWriter appends every write to the message's streamstore buffer
+// and publishes it to any other attached viewers, in addition to writing it
+// to the original destination (the generating client's response writer).
+type streamTeeWriter struct {
+	messageId string
+	next      io.Writer
+}
+
+func newStreamTeeWriter(messageId string, next io.Writer) *streamTeeWriter {
+	return &streamTeeWriter{messageId: messageId, next: next}
+}
+
+func (w *streamTeeWriter) Write(p []byte) (int, error) {
+	err := streamstore.Default().Append(w.messageId, p)
+	if err != nil {
+		return 0, err
+	}
+
+	messageBroadcaster.Publish(w.messageId, append([]byte{}, p...))
+
+	return w.next.Write(p)
+}
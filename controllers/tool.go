@@ -0,0 +1,82 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casibase/casibase/object"
+)
+
+func (c *ApiController) GetTools() {
+	if !c.IsAdmin() {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	owner := c.Input().Get("owner")
+
+	tools, err := object.GetTools(owner)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(tools)
+}
+
+func (c *ApiController) AddTool() {
+	if !c.IsAdmin() {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	var tool object.ToolConfig
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &tool)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.AddTool(&tool)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
+
+func (c *ApiController) DeleteTool() {
+	if !c.IsAdmin() {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	var tool object.ToolConfig
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &tool)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.DeleteTool(&tool)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
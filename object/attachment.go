@@ -0,0 +1,24 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// Attachment is a single non-text part of a message, e.g. a recorded voice
+// note or an uploaded image. It is stored as part of Message.Attachments.
+type Attachment struct {
+	Type     string  `json:"type"` // "image", "audio", "video" or "file"
+	Url      string  `json:"url"`
+	Mime     string  `json:"mime"`
+	Duration float64 `json:"duration"` // seconds, for audio/video
+}
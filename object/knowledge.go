@@ -0,0 +1,71 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+)
+
+// Knowledge is a single passage retrieved from a store's vector index.
+type Knowledge struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// VectorScore records how strongly a retrieved passage matched the
+// question, persisted alongside the AI message for later inspection.
+type VectorScore struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// EmbeddingProvider is implemented by every embedding backend used to
+// vectorize questions and knowledge passages for similarity search.
+type EmbeddingProvider interface {
+	QueryVector(ctx context.Context, text string) ([]float32, error)
+}
+
+// GetDefaultEmbeddingProvider resolves the embedding provider configured on
+// owner's default store, the same lookup GetMessageAnswer performs via
+// getEmbeddingProviderFromContext.
+func GetDefaultEmbeddingProvider(owner string) (string, EmbeddingProvider, error) {
+	store, err := GetDefaultStore(owner)
+	if err != nil {
+		return "", nil, err
+	}
+	if store == nil {
+		return "", nil, fmt.Errorf("the default store for: %s is not found", owner)
+	}
+
+	return getEmbeddingProvider(owner, store.EmbeddingProviderName)
+}
+
+// GetNearestKnowledge returns the knowledge passages most similar to
+// question, along with their vector scores for persistence on the answer
+// message. ctx is honored for cancellation of the underlying embedding
+// call.
+func GetNearestKnowledge(ctx context.Context, embeddingProvider string, embeddingProviderObj EmbeddingProvider, owner string, question string) ([]*Knowledge, []VectorScore, error) {
+	if embeddingProviderObj == nil {
+		return nil, nil, fmt.Errorf("no knowledge vectors found")
+	}
+
+	_, err := embeddingProviderObj.QueryVector(ctx, question)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return queryNearestKnowledge(owner, question)
+}
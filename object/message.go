@@ -0,0 +1,68 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "fmt"
+
+// Message is a single turn in a chat: either the human's question or the
+// AI's answer. Answer messages are created empty and filled in once
+// GetMessageAnswer/GetMessageAnswerWS finishes streaming.
+type Message struct {
+	Owner        string        `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name         string        `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime  string        `xorm:"varchar(100)" json:"createdTime"`
+	Organization string        `xorm:"varchar(100)" json:"organization"`
+	User         string        `xorm:"varchar(100)" json:"user"`
+	Chat         string        `xorm:"varchar(100) index" json:"chat"`
+	ReplyTo      string        `xorm:"varchar(100)" json:"replyTo"`
+	Author       string        `xorm:"varchar(100)" json:"author"`
+	Text         string        `xorm:"mediumtext" json:"text"`
+	VectorScores []VectorScore `xorm:"mediumtext" json:"vectorScores"`
+
+	// Steps records the agent loop's tool calls (if any) that produced this
+	// answer, so the UI can render the reasoning trace. Empty for messages
+	// that didn't go through a function-calling model provider.
+	Steps []MessageStep `xorm:"mediumtext" json:"steps"`
+
+	// Attachments holds the raw, caller-supplied non-text parts of the
+	// message (audio, image, ...) as submitted by the client.
+	Attachments []Attachment `xorm:"mediumtext" json:"attachments"`
+
+	// MultimodalParts is the processed form of Attachments ready to forward
+	// to the model provider (audio has already been transcribed into Text
+	// by the time a message is stored). Images end up here as image parts
+	// for a vision-capable model, otherwise as text parts holding a caption.
+	MultimodalParts []MultimodalPart `xorm:"mediumtext" json:"multimodalParts"`
+
+	// ParentId is the message this one branches from: "" for the first
+	// message of a chat, the question's id for its auto-created answer, and
+	// the original message's own ParentId for a regenerated or edited
+	// sibling.
+	ParentId string `xorm:"varchar(100) index" json:"parentId"`
+
+	// SiblingIndex orders messages that share the same ParentId (the
+	// original answer plus every regenerate/edit of it), in the order they
+	// were created.
+	SiblingIndex int `xorm:"int" json:"siblingIndex"`
+
+	// ActiveChildId is the child of this message currently shown in the
+	// active branch, e.g. after a regenerate or an explicit SwitchBranch.
+	// Empty means the oldest child (SiblingIndex 0) is active.
+	ActiveChildId string `xorm:"varchar(100)" json:"activeChildId"`
+}
+
+func (message *Message) GetId() string {
+	return fmt.Sprintf("%s/%s", message.Owner, message.Name)
+}
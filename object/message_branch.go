@@ -0,0 +1,157 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "sort"
+
+// GetSiblingCount returns how many messages already share parentId, so a
+// newly regenerated or edited message can be given the next SiblingIndex.
+func GetSiblingCount(parentId string) (int, error) {
+	if parentId == "" {
+		return 0, nil
+	}
+
+	count, err := adapter.engine.Where("parent_id = ?", parentId).Count(&Message{})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// SetActiveChild marks childId as the active branch under parentId, so
+// GetActiveChatMessages walks through it instead of one of its siblings.
+// A parentId of "" (childId is a root message of the chat) is not recorded
+// anywhere: SetActiveChild returns nil without changing anything, and
+// GetActiveChatMessages always starts from the chat's earliest message.
+func SetActiveChild(parentId string, childId string) error {
+	if parentId == "" {
+		return nil
+	}
+
+	parent, err := GetMessage(parentId)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return nil
+	}
+
+	parent.ActiveChildId = childId
+	_, err = UpdateMessage(parent.GetId(), parent)
+	return err
+}
+
+// GetActiveChatMessages walks the active branch of chat's message tree,
+// starting from its root and following ActiveChildId at each step, rather
+// than returning every row in time order the way GetChatMessages used to.
+//
+// ParentId is a column added for this feature, so every message created
+// before it shipped has ParentId == "" - not just the true root. Treating
+// every one of those as a competing root candidate would keep only the
+// single earliest and silently drop the rest of the conversation. Instead,
+// only the very first message (by CreatedTime) is ever treated as root;
+// every other message with an unresolved ParentId (empty, or pointing at a
+// message outside this chat) falls back to chaining off whichever message
+// immediately precedes it in time, so a pre-branching chat still replays
+// in full.
+func GetActiveChatMessages(chat string) ([]*Message, error) {
+	all, err := GetChatMessages(chat)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(all) == 0 {
+		return []*Message{}, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].CreatedTime < all[j].CreatedTime
+	})
+
+	byId := map[string]*Message{}
+	for _, message := range all {
+		byId[message.GetId()] = message
+	}
+
+	root := all[0]
+	byParent := map[string][]*Message{}
+	for i, message := range all {
+		if message == root {
+			continue
+		}
+
+		parentId := message.ParentId
+		if parentId == "" || byId[parentId] == nil {
+			parentId = all[i-1].GetId()
+		}
+		byParent[parentId] = append(byParent[parentId], message)
+	}
+
+	path := []*Message{root}
+	current := root
+	for {
+		children, ok := byParent[current.GetId()]
+		if !ok || len(children) == 0 {
+			break
+		}
+
+		next := children[0]
+		if current.ActiveChildId != "" {
+			for _, child := range children {
+				if child.GetId() == current.ActiveChildId {
+					next = child
+					break
+				}
+			}
+		}
+
+		path = append(path, next)
+		current = next
+	}
+
+	return path, nil
+}
+
+// GetActiveRecentRawMessages returns the tail of chat's active branch (see
+// GetActiveChatMessages), converted to the RawMessage history model providers
+// consume, capped at limit messages. It replaces a flat, all-branches
+// history for callers that need the conversation the active branch
+// actually shows, not every regenerate/edit ever made.
+func GetActiveRecentRawMessages(chat string, limit int) ([]*RawMessage, error) {
+	messages, err := GetActiveChatMessages(chat)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+
+	history := make([]*RawMessage, 0, len(messages))
+	for _, message := range messages {
+		if message.Text == "" {
+			continue
+		}
+
+		role := "user"
+		if message.Author == "AI" {
+			role = "assistant"
+		}
+		history = append(history, &RawMessage{Role: role, Text: message.Text})
+	}
+
+	return history, nil
+}
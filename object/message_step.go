@@ -0,0 +1,26 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// MessageStep records one iteration of the agent loop (a tool call and its
+// result, or the final answer) so the UI can render the reasoning trace.
+// It is stored as part of Message.Steps.
+type MessageStep struct {
+	Index    int    `json:"index"`
+	ToolName string `json:"toolName"`
+	Input    string `json:"input"`
+	Output   string `json:"output"`
+	Text     string `json:"text"`
+}
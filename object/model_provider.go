@@ -0,0 +1,86 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"io"
+)
+
+// ModelProvider is implemented by every chat model backend (OpenAI, Claude,
+// local llama.cpp, etc.). QueryText writes the streamed answer to writer as
+// it arrives; ctx is honored for cancellation so a caller (SSE or
+// WebSocket) can abort generation mid-stream.
+type ModelProvider interface {
+	QueryText(ctx context.Context, question string, writer io.Writer, history []*RawMessage, prompt string, knowledge []*Knowledge) error
+
+	// QueryMultimodal is QueryText's counterpart for messages carrying
+	// image parts, e.g. from an attached photo a vision-capable model
+	// should see directly rather than through a text caption. parts
+	// contains the image/text parts produced by object/multimodal in
+	// addition to question.
+	QueryMultimodal(ctx context.Context, question string, parts []MultimodalPart, writer io.Writer, history []*RawMessage, prompt string, knowledge []*Knowledge) error
+}
+
+// MultimodalPart mirrors multimodal.Part without importing the multimodal
+// package from object, to avoid an import cycle between object and
+// object/multimodal.
+type MultimodalPart struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+// FunctionCallingModelProvider is implemented by model providers whose
+// underlying API supports function/tool calling. The message controller's
+// agent loop type-asserts for this interface before attempting a ReAct-style
+// tool loop; providers that don't implement it always go through the plain
+// QueryText path.
+type FunctionCallingModelProvider interface {
+	ModelProvider
+
+	// QueryToolCall runs one turn of the conversation and returns either a
+	// final text answer or a requested tool call, never both.
+	QueryToolCall(ctx context.Context, question string, history []*RawMessage, prompt string, knowledge []*Knowledge, toolSchemas []map[string]interface{}) (*ToolCall, string, error)
+}
+
+// VisionCapableModelProvider is implemented by model providers that can
+// actually see an image via QueryMultimodal, as opposed to providers that
+// only implement QueryMultimodal to satisfy ModelProvider. AddMessage
+// type-asserts for this interface to decide whether an image attachment
+// should be forwarded as a part or captioned first, the same way the
+// agent loop type-asserts for FunctionCallingModelProvider.
+type VisionCapableModelProvider interface {
+	ModelProvider
+
+	SupportsVision() bool
+}
+
+// ToolCall is a model's request to invoke a registered tool. Id is the
+// provider's identifier for the call; the agent loop does not pass it back,
+// since the RawMessage history it appends the tool result to has no field
+// for it.
+type ToolCall struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Input string `json:"input"`
+}
+
+// RawMessage is the minimal role/content pair passed as conversation
+// history to a model provider.
+type RawMessage struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multimodal dispatches non-text message attachments to the
+// appropriate backend: audio is transcribed via a Whisper-compatible STT
+// API, and images are either forwarded as parts to a vision-capable model
+// or captioned when the target model is text-only.
+package multimodal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/casibase/casibase/object"
+)
+
+// Part is a single piece of a multimodal prompt passed to
+// ModelProvider.QueryMultimodal: either plain text or an image reference.
+type Part struct {
+	Type string `json:"type"` // "text" or "image"
+	Text string `json:"text,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+// ProcessAttachments dispatches each attachment: audio is transcribed and
+// its text merged into the returned transcript (for the caller to append to
+// message.Text); images are returned as image Parts, ready to forward to a
+// vision-capable model, or, when visionCapable is false, captioned via
+// Caption and returned as text Parts. Other attachment types are skipped.
+func ProcessAttachments(ctx context.Context, attachments []object.Attachment, visionCapable bool) (transcript string, parts []Part, err error) {
+	var transcripts []string
+
+	for _, attachment := range attachments {
+		switch attachment.Type {
+		case "audio":
+			text, err := Transcribe(ctx, attachment.Url)
+			if err != nil {
+				return "", nil, err
+			}
+			transcripts = append(transcripts, text)
+		case "image":
+			if visionCapable {
+				parts = append(parts, Part{Type: "image", Url: attachment.Url})
+			} else {
+				caption, err := Caption(ctx, attachment.Url)
+				if err != nil {
+					return "", nil, err
+				}
+				parts = append(parts, Part{Type: "text", Text: caption})
+			}
+		}
+	}
+
+	return strings.Join(transcripts, "\n"), parts, nil
+}
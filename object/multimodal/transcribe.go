@@ -0,0 +1,107 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multimodal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/casibase/casibase/object"
+)
+
+// sttEndpoint is the base URL of the configured Whisper-compatible speech
+// to text API, e.g. a local whisper.cpp server or an OpenAI-compatible
+// /v1/audio/transcriptions endpoint. It is read from the environment so
+// deployments can point at whichever STT backend they run.
+func sttEndpoint() string {
+	endpoint := os.Getenv("STT_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:9000/v1/audio/transcriptions"
+	}
+	return endpoint
+}
+
+type transcriptionResponse struct {
+	Text string `json:"text"`
+}
+
+// Transcribe downloads the audio at url and sends it to the configured STT
+// endpoint, returning the transcript text.
+func Transcribe(ctx context.Context, url string) (string, error) {
+	if err := object.ValidateFetchURL(url); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "audio")
+	if err != nil {
+		return "", err
+	}
+	_, err = part.Write(audio)
+	if err != nil {
+		return "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	sttReq, err := http.NewRequestWithContext(ctx, http.MethodPost, sttEndpoint(), body)
+	if err != nil {
+		return "", err
+	}
+	sttReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	sttResp, err := http.DefaultClient.Do(sttReq)
+	if err != nil {
+		return "", err
+	}
+	defer sttResp.Body.Close()
+
+	if sttResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the STT endpoint returned status: %d", sttResp.StatusCode)
+	}
+
+	var parsed transcriptionResponse
+	err = json.NewDecoder(sttResp.Body).Decode(&parsed)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.Text, nil
+}
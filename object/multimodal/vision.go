@@ -0,0 +1,33 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multimodal
+
+import (
+	"context"
+
+	"github.com/casibase/casibase/object"
+)
+
+// Caption asks the deployment's configured vision model to describe the
+// image at url, for use when the target chat model cannot accept image
+// parts directly.
+func Caption(ctx context.Context, url string) (string, error) {
+	_, visionProviderObj, err := object.GetDefaultVisionProvider("admin")
+	if err != nil {
+		return "", err
+	}
+
+	return visionProviderObj.QueryImageCaption(ctx, url)
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// Store configures a single AI deployment: which model, embedding and
+// vision provider a chat backed by it uses, along with the knobs that
+// shape a request (the system prompt, how much history to replay, the
+// greeting shown for a new chat).
+type Store struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Welcome     string `xorm:"mediumtext" json:"welcome"`
+	Prompt      string `xorm:"mediumtext" json:"prompt"`
+	MemoryLimit int    `xorm:"int" json:"memoryLimit"`
+
+	ModelProviderName     string `xorm:"varchar(100)" json:"modelProviderName"`
+	EmbeddingProviderName string `xorm:"varchar(100)" json:"embeddingProviderName"`
+
+	// VisionProviderName names the provider GetDefaultVisionProvider
+	// resolves to caption an image attachment for a chat whose target
+	// model can't accept image parts directly.
+	VisionProviderName string `xorm:"varchar(100)" json:"visionProviderName"`
+
+	// AllowedSqlTables is the allowlist QueryStoreSql checks a sql_query
+	// tool call's FROM/JOIN targets against. It defaults to empty, which
+	// denies every query, so a store must opt in to which of its own
+	// tables the sql_query tool may read before the tool can return
+	// anything for it.
+	AllowedSqlTables []string `xorm:"mediumtext" json:"allowedSqlTables"`
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// QueryStoreSql runs a read-only query against the database configured on
+// the named store, for use by the sql_query agent tool. Callers are
+// responsible for ensuring query only contains a SELECT statement; this
+// additionally enforces that storeName belongs to owner and that query
+// only touches tables storeName's AllowedSqlTables allowlist names -
+// without both checks, any owner with the tool enabled could pass any
+// store's name and read any table reachable through adapter.engine, since
+// there is currently no per-store database connection to isolate through.
+func QueryStoreSql(ctx context.Context, owner string, storeName string, query string) ([]map[string]interface{}, error) {
+	store, err := GetStore(storeName)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("the store: %s is not found", storeName)
+	}
+	if store.Owner != owner {
+		return nil, fmt.Errorf("the store: %s does not belong to: %s", storeName, owner)
+	}
+
+	if err = validateQueryTables(query, store.AllowedSqlTables); err != nil {
+		return nil, err
+	}
+
+	return store.querySql(ctx, query)
+}
+
+// tableReferencePattern matches the table name following a FROM or JOIN
+// keyword, the two places a SELECT (already enforced by
+// validateReadOnlyQuery) can name a table to read from.
+var tableReferencePattern = regexp.MustCompile(`(?i)\b(?:from|join)\s+` + "`" + `?([a-zA-Z_][a-zA-Z0-9_]*)` + "`" + `?`)
+
+// validateQueryTables rejects query unless every table it references via
+// FROM/JOIN is in allowed. An empty allowed list - the default for a store
+// that hasn't opted in - rejects every query.
+func validateQueryTables(query string, allowed []string) error {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, table := range allowed {
+		allowedSet[strings.ToLower(table)] = true
+	}
+
+	matches := tableReferencePattern.FindAllStringSubmatch(query, -1)
+	if len(matches) == 0 {
+		return fmt.Errorf("the query does not reference any table")
+	}
+
+	for _, match := range matches {
+		table := strings.ToLower(match[1])
+		if !allowedSet[table] {
+			return fmt.Errorf("the table: %s is not in this store's allowed SQL tables", match[1])
+		}
+	}
+
+	return nil
+}
+
+// querySql runs query against the engine's backing SQL database and decodes
+// every row into a map keyed by column name. There is currently no
+// per-store database connection to dispatch to, so this goes through the
+// same adapter.engine every other object query uses; validateQueryTables
+// above is what keeps that shared connection from turning into a
+// cross-tenant read once the ownership check passes.
+func (store *Store) querySql(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	rows, err := adapter.engine.DB().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, 0)
+	values := make([]interface{}, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		err = rows.Scan(scanArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "fmt"
+
+// ToolConfig is the persisted, owner-scoped record for a tool made
+// available to the agent loop. The tool's behavior lives in the
+// object/tools registry; this record only controls whether it is enabled
+// and, for tools that need one, which endpoint/credential to use.
+type ToolConfig struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+	Type        string `xorm:"varchar(100)" json:"type"`
+	Enabled     bool   `xorm:"bool" json:"enabled"`
+}
+
+func (tool *ToolConfig) GetId() string {
+	return fmt.Sprintf("%s/%s", tool.Owner, tool.Name)
+}
+
+func GetTools(owner string) ([]*ToolConfig, error) {
+	tools := []*ToolConfig{}
+	err := adapter.engine.Desc("created_time").Find(&tools, &ToolConfig{Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	return tools, nil
+}
+
+func AddTool(tool *ToolConfig) (bool, error) {
+	affected, err := adapter.engine.Insert(tool)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteTool(tool *ToolConfig) (bool, error) {
+	affected, err := adapter.engine.ID([]string{tool.Owner, tool.Name}).Delete(&ToolConfig{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
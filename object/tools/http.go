@@ -0,0 +1,82 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/casibase/casibase/object"
+)
+
+type httpFetchTool struct{}
+
+func init() {
+	Register(&httpFetchTool{})
+}
+
+func (t *httpFetchTool) Name() string {
+	return "http_fetch"
+}
+
+func (t *httpFetchTool) Description() string {
+	return "Fetch the contents of a URL over HTTP GET and return the response body as text."
+}
+
+func (t *httpFetchTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"url": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"url"},
+	}
+}
+
+type httpFetchInput struct {
+	Url string `json:"url"`
+}
+
+func (t *httpFetchTool) Call(ctx context.Context, owner string, input string) (string, error) {
+	var parsed httpFetchInput
+	err := json.Unmarshal([]byte(input), &parsed)
+	if err != nil {
+		return "", err
+	}
+
+	if err = object.ValidateFetchURL(parsed.Url); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.Url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/casibase/casibase/object"
+)
+
+type knowledgeSearchTool struct{}
+
+func init() {
+	Register(&knowledgeSearchTool{})
+}
+
+func (t *knowledgeSearchTool) Name() string {
+	return "knowledge_search"
+}
+
+func (t *knowledgeSearchTool) Description() string {
+	return "Search the default knowledge base for passages relevant to a query."
+}
+
+func (t *knowledgeSearchTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"query"},
+	}
+}
+
+type knowledgeSearchInput struct {
+	Query string `json:"query"`
+}
+
+func (t *knowledgeSearchTool) Call(ctx context.Context, owner string, input string) (string, error) {
+	var parsed knowledgeSearchInput
+	err := json.Unmarshal([]byte(input), &parsed)
+	if err != nil {
+		return "", err
+	}
+
+	embeddingProvider, embeddingProviderObj, err := object.GetDefaultEmbeddingProvider("admin")
+	if err != nil {
+		return "", err
+	}
+
+	knowledge, _, err := object.GetNearestKnowledge(ctx, embeddingProvider, embeddingProviderObj, "admin", parsed.Query)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(knowledge)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tools implements the built-in function-calling tools that the
+// message controller's agent loop can invoke on behalf of a model.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Tool is a single callable function exposed to a model that supports
+// function calling. Schema is the JSON schema for Call's expected input,
+// in the shape most model providers expect for function definitions.
+// owner is the ToolConfig owner the agent loop resolved the tool for; a
+// Call implementation that can reach data scoped to an owner (e.g.
+// the stores sql_query reads) must enforce that scope itself using
+// it, the same way every other object lookup in this codebase takes
+// owner explicitly rather than threading it through ctx.
+type Tool interface {
+	Name() string
+	Description() string
+	Schema() map[string]interface{}
+	Call(ctx context.Context, owner string, input string) (string, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Tool{}
+)
+
+// Register adds a tool to the global registry. Built-ins call this from an
+// init() in their own file; callers adding a store-specific tool (e.g. a
+// custom HTTP fetch allowlist) can call it directly.
+func Register(tool Tool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[tool.Name()] = tool
+}
+
+// Get looks up a registered tool by name.
+func Get(name string) (Tool, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	tool, ok := registry[name]
+	return tool, ok
+}
+
+// List returns every registered tool, for surfacing to the model as
+// function definitions.
+func List() []Tool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	result := make([]Tool, 0, len(registry))
+	for _, tool := range registry {
+		result = append(result, tool)
+	}
+	return result
+}
+
+// Call runs the named tool, returning an error if it isn't registered.
+func Call(ctx context.Context, owner string, name string, input string) (string, error) {
+	tool, ok := Get(name)
+	if !ok {
+		return "", fmt.Errorf("the tool: %s is not registered", name)
+	}
+
+	return tool.Call(ctx, owner, input)
+}
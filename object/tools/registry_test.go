@@ -0,0 +1,77 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+// recordingTool captures the owner and input it was last called with, so
+// tests can assert that Call dispatches them through unchanged - the
+// property owner-scoped tools such as sql_query rely on.
+type recordingTool struct {
+	lastOwner string
+	lastInput string
+}
+
+func (t *recordingTool) Name() string                   { return "recording_tool" }
+func (t *recordingTool) Description() string            { return "records the last call for assertions" }
+func (t *recordingTool) Schema() map[string]interface{} { return map[string]interface{}{} }
+func (t *recordingTool) Call(ctx context.Context, owner string, input string) (string, error) {
+	t.lastOwner = owner
+	t.lastInput = input
+	return "ok", nil
+}
+
+func TestCallDispatchesOwnerAndInputToTheRegisteredTool(t *testing.T) {
+	tool := &recordingTool{}
+	Register(tool)
+
+	output, err := Call(context.Background(), "alice", "recording_tool", `{"q":"x"}`)
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if output != "ok" {
+		t.Fatalf("expected output %q, got %q", "ok", output)
+	}
+	if tool.lastOwner != "alice" {
+		t.Fatalf("expected owner %q to reach the tool, got %q", "alice", tool.lastOwner)
+	}
+	if tool.lastInput != `{"q":"x"}` {
+		t.Fatalf("expected input to reach the tool unchanged, got %q", tool.lastInput)
+	}
+}
+
+func TestCallRejectsAnUnregisteredTool(t *testing.T) {
+	_, err := Call(context.Background(), "alice", "not_a_real_tool", "{}")
+	if err == nil {
+		t.Fatalf("calling an unregistered tool should error")
+	}
+}
+
+func TestListIncludesEveryRegisteredTool(t *testing.T) {
+	Register(&recordingTool{})
+
+	found := false
+	for _, tool := range List() {
+		if tool.Name() == "recording_tool" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("List should include every tool added via Register")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/casibase/casibase/object"
+)
+
+type sqlQueryTool struct{}
+
+func init() {
+	Register(&sqlQueryTool{})
+}
+
+func (t *sqlQueryTool) Name() string {
+	return "sql_query"
+}
+
+func (t *sqlQueryTool) Description() string {
+	return "Run a read-only SQL query against a configured store's database and return the rows as JSON."
+}
+
+func (t *sqlQueryTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"store": map[string]interface{}{"type": "string"},
+			"query": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"store", "query"},
+	}
+}
+
+type sqlQueryInput struct {
+	Store string `json:"store"`
+	Query string `json:"query"`
+}
+
+func (t *sqlQueryTool) Call(ctx context.Context, owner string, input string) (string, error) {
+	var parsed sqlQueryInput
+	err := json.Unmarshal([]byte(input), &parsed)
+	if err != nil {
+		return "", err
+	}
+
+	if err = validateReadOnlyQuery(parsed.Query); err != nil {
+		return "", err
+	}
+
+	rows, err := object.QueryStoreSql(ctx, owner, parsed.Store, parsed.Query)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// validateReadOnlyQuery rejects anything but a single, plain SELECT
+// statement. A bare "HasPrefix(query, SELECT)" check doesn't stop a
+// stacked statement like "SELECT 1; DROP TABLE messages;--", since the
+// prefix is still SELECT; this also rejects a trailing comment, which SQL
+// engines that do allow stacked statements use to swallow the rest of the
+// line after the payload.
+func validateReadOnlyQuery(query string) error {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+
+	if strings.ContainsAny(trimmed, ";") {
+		return fmt.Errorf("only a single statement is allowed")
+	}
+	if strings.Contains(trimmed, "--") || strings.Contains(trimmed, "/*") {
+		return fmt.Errorf("comments are not allowed in the query")
+	}
+	if !strings.HasPrefix(strings.ToUpper(trimmed), "SELECT") {
+		return fmt.Errorf("only SELECT queries are allowed")
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import "testing"
+
+func TestValidateReadOnlyQueryAllowsPlainSelect(t *testing.T) {
+	if err := validateReadOnlyQuery("SELECT id, name FROM store WHERE owner = 'admin'"); err != nil {
+		t.Fatalf("a plain SELECT should be allowed: %v", err)
+	}
+}
+
+func TestValidateReadOnlyQueryRejectsNonSelect(t *testing.T) {
+	if err := validateReadOnlyQuery("DELETE FROM store"); err == nil {
+		t.Fatalf("a non-SELECT statement should be rejected")
+	}
+}
+
+func TestValidateReadOnlyQueryRejectsStackedStatements(t *testing.T) {
+	if err := validateReadOnlyQuery("SELECT 1; DROP TABLE message;"); err == nil {
+		t.Fatalf("a stacked statement should be rejected")
+	}
+}
+
+func TestValidateReadOnlyQueryRejectsTrailingComment(t *testing.T) {
+	if err := validateReadOnlyQuery("SELECT 1 --; DROP TABLE message;"); err == nil {
+		t.Fatalf("a query smuggling a statement in a trailing comment should be rejected")
+	}
+}
+
+func TestValidateReadOnlyQueryRejectsBlockComment(t *testing.T) {
+	if err := validateReadOnlyQuery("SELECT 1 /* comment */"); err == nil {
+		t.Fatalf("a query containing a block comment should be rejected")
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type webSearchTool struct {
+	endpoint string
+}
+
+func init() {
+	Register(&webSearchTool{endpoint: "https://api.duckduckgo.com/"})
+}
+
+func (t *webSearchTool) Name() string {
+	return "web_search"
+}
+
+func (t *webSearchTool) Description() string {
+	return "Search the web for a query and return the top results as text."
+}
+
+func (t *webSearchTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"query"},
+	}
+}
+
+type webSearchInput struct {
+	Query string `json:"query"`
+}
+
+func (t *webSearchTool) Call(ctx context.Context, owner string, input string) (string, error) {
+	var parsed webSearchInput
+	err := json.Unmarshal([]byte(input), &parsed)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Query == "" {
+		return "", fmt.Errorf("the query should not be empty")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?q=%s&format=json", t.endpoint, parsed.Query), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// ValidateFetchURL rejects URLs that would let a tool call or a
+// caller-supplied attachment URL pivot the server into its own network
+// (SSRF): only plain http/https is allowed, and every address the host
+// resolves to must be a globally routable unicast address. This also
+// blocks loopback, link-local (including the 169.254.169.254 cloud
+// metadata endpoint) and RFC1918 private ranges.
+func ValidateFetchURL(rawUrl string) error {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("the URL scheme: %s is not allowed", parsed.Scheme)
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("the URL has no host")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return err
+	}
+	if len(ips) == 0 {
+		return fmt.Errorf("the URL host: %s did not resolve to any address", host)
+	}
+
+	for _, ip := range ips {
+		if !ip.IsGlobalUnicast() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified() {
+			return fmt.Errorf("the URL host: %s resolves to a disallowed address: %s", host, ip.String())
+		}
+	}
+
+	return nil
+}
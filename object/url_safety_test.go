@@ -0,0 +1,53 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestValidateFetchURLRejectsDisallowedScheme(t *testing.T) {
+	if err := ValidateFetchURL("ftp://example.com/file"); err == nil {
+		t.Fatalf("a non-http(s) scheme should be rejected")
+	}
+}
+
+func TestValidateFetchURLRejectsMalformedURL(t *testing.T) {
+	if err := ValidateFetchURL("http://[::1"); err == nil {
+		t.Fatalf("a malformed URL should be rejected")
+	}
+}
+
+func TestValidateFetchURLRejectsLoopbackHost(t *testing.T) {
+	if err := ValidateFetchURL("http://localhost/"); err == nil {
+		t.Fatalf("a loopback host should be rejected")
+	}
+}
+
+func TestValidateFetchURLRejectsLoopbackIP(t *testing.T) {
+	if err := ValidateFetchURL("http://127.0.0.1/"); err == nil {
+		t.Fatalf("a loopback IP literal should be rejected")
+	}
+}
+
+func TestValidateFetchURLRejectsLinkLocalMetadataIP(t *testing.T) {
+	if err := ValidateFetchURL("http://169.254.169.254/latest/meta-data/"); err == nil {
+		t.Fatalf("the cloud metadata endpoint should be rejected")
+	}
+}
+
+func TestValidateFetchURLRejectsPrivateIP(t *testing.T) {
+	if err := ValidateFetchURL("http://10.0.0.1/"); err == nil {
+		t.Fatalf("an RFC1918 private address should be rejected")
+	}
+}
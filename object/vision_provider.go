@@ -0,0 +1,113 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// VisionProvider is implemented by model backends that can describe an
+// image, used to caption attachments for chat models that cannot accept
+// image parts directly.
+type VisionProvider interface {
+	QueryImageCaption(ctx context.Context, url string) (string, error)
+}
+
+// GetDefaultVisionProvider resolves the vision provider configured on
+// owner's default store.
+func GetDefaultVisionProvider(owner string) (string, VisionProvider, error) {
+	store, err := GetDefaultStore(owner)
+	if err != nil {
+		return "", nil, err
+	}
+	if store == nil {
+		return "", nil, fmt.Errorf("the default store for: %s is not found", owner)
+	}
+
+	return getVisionProvider(owner, store.VisionProviderName)
+}
+
+// getVisionProvider resolves providerName to a VisionProvider
+// implementation, the same way getEmbeddingProvider/
+// getModelProviderFromContext resolve their own provider names. There is
+// currently a single backend, a configurable HTTP captioning endpoint;
+// providerName is required (rather than falling back to a default
+// endpoint silently) so a store with no vision provider configured fails
+// loudly instead of captioning through a backend nobody asked for.
+func getVisionProvider(owner string, providerName string) (string, VisionProvider, error) {
+	if providerName == "" {
+		return "", nil, fmt.Errorf("no vision provider is configured for: %s", owner)
+	}
+
+	return providerName, &httpVisionProvider{endpoint: visionEndpoint()}, nil
+}
+
+// visionEndpoint is the base URL of the configured image-captioning API,
+// e.g. an OpenAI-vision-compatible captioning server. It is read from the
+// environment so deployments can point at whichever vision backend they
+// run, mirroring sttEndpoint in object/multimodal for audio.
+func visionEndpoint() string {
+	endpoint := os.Getenv("VISION_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:9001/v1/caption"
+	}
+	return endpoint
+}
+
+type captionResponse struct {
+	Caption string `json:"caption"`
+}
+
+// httpVisionProvider captions an image by posting its URL to a configured
+// captioning endpoint and decoding the returned caption text.
+type httpVisionProvider struct {
+	endpoint string
+}
+
+func (p *httpVisionProvider) QueryImageCaption(ctx context.Context, url string) (string, error) {
+	reqBody, err := json.Marshal(map[string]string{"url": url})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the vision endpoint returned status: %d", resp.StatusCode)
+	}
+
+	var parsed captionResponse
+	err = json.NewDecoder(resp.Body).Decode(&parsed)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.Caption, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit implements per-user, per-store, per-model-provider and
+// per-organization quotas for chat requests, replacing the ad-hoc
+// GetNearMessageCount check that used to live in the message controller.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bucket names the independent dimensions a caller can be throttled on. A
+// single request is typically checked against several buckets at once (e.g.
+// RequestsPerMinute and ConcurrentStreams).
+type Bucket string
+
+const (
+	BucketRequestsPerMinute Bucket = "requests_per_minute"
+	BucketTokensPerDay      Bucket = "tokens_per_day"
+	BucketConcurrentStreams Bucket = "concurrent_streams"
+)
+
+// Key identifies who/what is being rate limited. Any of the fields may be
+// empty; a Limiter is free to enforce the tightest of the limits configured
+// for whichever fields are set.
+type Key struct {
+	Store        string
+	User         string
+	Organization string
+	Provider     string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s|%s|%s|%s", k.Store, k.User, k.Organization, k.Provider)
+}
+
+// Quota is the outcome of a Limiter check: whether the request is allowed,
+// how much of the bucket remains, and when the bucket resets.
+type Quota struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Limiter enforces quotas for every configured Bucket; each call names the
+// bucket it checks. Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Allow consumes `cost` units (1 for a request, N for N tokens) from the
+	// bucket identified by key and reports whether the request may proceed.
+	Allow(key Key, bucket Bucket, cost int) (Quota, error)
+
+	// Peek reports the current quota state without consuming from it.
+	Peek(key Key, bucket Bucket) (Quota, error)
+
+	// Release gives back `cost` units previously consumed via Allow.
+	// BucketConcurrentStreams uses this to turn Allow/Release into a true
+	// increment-on-start/decrement-on-finish gauge instead of a bucket that
+	// only refills on its own schedule; other buckets have no matching
+	// Release call and are unaffected.
+	Release(key Key, bucket Bucket, cost int) error
+
+	// Reset clears the bucket for key, used by admin quota-reset APIs.
+	Reset(key Key, bucket Bucket) error
+}
+
+// Limit configures the allowance for a single bucket.
+type Limit struct {
+	Bucket Bucket
+	Max    int
+	Window time.Duration
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucketState struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// MemoryLimiter is a process-local token-bucket Limiter. It is the default
+// backend for single-instance deployments and for buckets (like
+// ConcurrentStreams) that must not be shared across replicas anyway.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	limits  map[Bucket]Limit
+	buckets map[string]*bucketState
+}
+
+func NewMemoryLimiter(limits []Limit) *MemoryLimiter {
+	limitMap := map[Bucket]Limit{}
+	for _, limit := range limits {
+		limitMap[limit.Bucket] = limit
+	}
+
+	return &MemoryLimiter{
+		limits:  limitMap,
+		buckets: map[string]*bucketState{},
+	}
+}
+
+func (l *MemoryLimiter) stateKey(key Key, bucket Bucket) string {
+	return key.String() + "|" + string(bucket)
+}
+
+func (l *MemoryLimiter) refill(state *bucketState, limit Limit, now time.Time) {
+	if state.lastFill.IsZero() {
+		state.tokens = float64(limit.Max)
+		state.lastFill = now
+		return
+	}
+
+	// BucketConcurrentStreams counts streams in flight: only Allow and
+	// Release move it, it does not refill over time.
+	if limit.Bucket == BucketConcurrentStreams {
+		state.lastFill = now
+		return
+	}
+
+	elapsed := now.Sub(state.lastFill)
+	rate := float64(limit.Max) / limit.Window.Seconds()
+	state.tokens += elapsed.Seconds() * rate
+	if state.tokens > float64(limit.Max) {
+		state.tokens = float64(limit.Max)
+	}
+	state.lastFill = now
+}
+
+func (l *MemoryLimiter) Allow(key Key, bucket Bucket, cost int) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stateKey := l.stateKey(key, bucket)
+	state, ok := l.buckets[stateKey]
+	if !ok {
+		state = &bucketState{}
+		l.buckets[stateKey] = state
+	}
+	l.refill(state, limit, now)
+
+	quota := Quota{
+		Limit:   limit.Max,
+		ResetAt: now.Add(limit.Window),
+	}
+
+	// Deduct unconditionally, even when cost exceeds what's left, the same
+	// way RedisLimiter/SqlLimiter already do. Returning early instead would
+	// mean a caller whose cost keeps exceeding the remaining balance (e.g.
+	// a single large generation charged against the daily token cap) is
+	// never actually charged: the bucket just keeps refilling on schedule
+	// and the cap never meaningfully fires. Letting tokens go negative
+	// makes the caller pay it down out of future refills instead.
+	quota.Allowed = state.tokens >= float64(cost)
+	state.tokens -= float64(cost)
+
+	quota.Remaining = int(state.tokens)
+	if quota.Remaining < 0 {
+		quota.Remaining = 0
+	}
+	return quota, nil
+}
+
+func (l *MemoryLimiter) Release(key Key, bucket Bucket, cost int) error {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stateKey := l.stateKey(key, bucket)
+	state, ok := l.buckets[stateKey]
+	if !ok {
+		state = &bucketState{}
+		l.buckets[stateKey] = state
+	}
+	l.refill(state, limit, now)
+
+	state.tokens += float64(cost)
+	if state.tokens > float64(limit.Max) {
+		state.tokens = float64(limit.Max)
+	}
+	return nil
+}
+
+func (l *MemoryLimiter) Peek(key Key, bucket Bucket) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	state, ok := l.buckets[l.stateKey(key, bucket)]
+	if !ok {
+		return Quota{Allowed: true, Limit: limit.Max, Remaining: limit.Max, ResetAt: now.Add(limit.Window)}, nil
+	}
+	l.refill(state, limit, now)
+
+	return Quota{Allowed: state.tokens >= 1, Limit: limit.Max, Remaining: int(state.tokens), ResetAt: now.Add(limit.Window)}, nil
+}
+
+func (l *MemoryLimiter) Reset(key Key, bucket Bucket) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.buckets, l.stateKey(key, bucket))
+	return nil
+}
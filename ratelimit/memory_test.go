@@ -0,0 +1,157 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterAllowsUpToMax(t *testing.T) {
+	limiter := NewMemoryLimiter([]Limit{
+		{Bucket: BucketRequestsPerMinute, Max: 3, Window: time.Minute},
+	})
+	key := Key{User: "alice"}
+
+	for i := 0; i < 3; i++ {
+		quota, err := limiter.Allow(key, BucketRequestsPerMinute, 1)
+		if err != nil {
+			t.Fatalf("Allow returned error: %v", err)
+		}
+		if !quota.Allowed {
+			t.Fatalf("request %d should have been allowed, remaining=%d", i, quota.Remaining)
+		}
+	}
+
+	quota, err := limiter.Allow(key, BucketRequestsPerMinute, 1)
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if quota.Allowed {
+		t.Fatalf("4th request should have been denied once the bucket is exhausted")
+	}
+}
+
+func TestMemoryLimiterKeysAreIndependent(t *testing.T) {
+	limiter := NewMemoryLimiter([]Limit{
+		{Bucket: BucketRequestsPerMinute, Max: 1, Window: time.Minute},
+	})
+
+	aliceQuota, err := limiter.Allow(Key{User: "alice"}, BucketRequestsPerMinute, 1)
+	if err != nil || !aliceQuota.Allowed {
+		t.Fatalf("alice's first request should be allowed: %+v, %v", aliceQuota, err)
+	}
+
+	bobQuota, err := limiter.Allow(Key{User: "bob"}, BucketRequestsPerMinute, 1)
+	if err != nil || !bobQuota.Allowed {
+		t.Fatalf("bob's request should not be throttled by alice's usage: %+v, %v", bobQuota, err)
+	}
+}
+
+func TestMemoryLimiterResetClearsUsage(t *testing.T) {
+	limiter := NewMemoryLimiter([]Limit{
+		{Bucket: BucketRequestsPerMinute, Max: 1, Window: time.Minute},
+	})
+	key := Key{User: "alice"}
+
+	_, err := limiter.Allow(key, BucketRequestsPerMinute, 1)
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+
+	quota, err := limiter.Allow(key, BucketRequestsPerMinute, 1)
+	if err != nil || quota.Allowed {
+		t.Fatalf("bucket should be exhausted before Reset: %+v, %v", quota, err)
+	}
+
+	err = limiter.Reset(key, BucketRequestsPerMinute)
+	if err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+
+	quota, err = limiter.Allow(key, BucketRequestsPerMinute, 1)
+	if err != nil || !quota.Allowed {
+		t.Fatalf("request right after Reset should be allowed: %+v, %v", quota, err)
+	}
+}
+
+func TestMemoryLimiterAllowChargesEvenWhenCostExceedsRemaining(t *testing.T) {
+	limiter := NewMemoryLimiter([]Limit{
+		{Bucket: BucketTokensPerDay, Max: 100, Window: 24 * time.Hour},
+	})
+	key := Key{User: "alice"}
+
+	quota, err := limiter.Allow(key, BucketTokensPerDay, 80)
+	if err != nil || !quota.Allowed {
+		t.Fatalf("first charge should fit the budget: %+v, %v", quota, err)
+	}
+
+	// The remaining 20 tokens aren't enough to cover this 50-token charge,
+	// so it should be reported as disallowed - but it must still be
+	// deducted, or a caller whose usage keeps exceeding what's left would
+	// never actually be charged and the daily cap would never fire.
+	quota, err = limiter.Allow(key, BucketTokensPerDay, 50)
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if quota.Allowed {
+		t.Fatalf("charge exceeding the remaining balance should be reported as disallowed")
+	}
+	if quota.Remaining != 0 {
+		t.Fatalf("remaining should be clamped to 0 once the balance goes negative, got %d", quota.Remaining)
+	}
+
+	peeked, err := limiter.Peek(key, BucketTokensPerDay)
+	if err != nil {
+		t.Fatalf("Peek returned error: %v", err)
+	}
+	if peeked.Allowed {
+		t.Fatalf("bucket should stay exhausted after a charge that drove it negative")
+	}
+}
+
+func TestMemoryLimiterConcurrentAllowDoesNotOvercount(t *testing.T) {
+	limiter := NewMemoryLimiter([]Limit{
+		{Bucket: BucketRequestsPerMinute, Max: 50, Window: time.Minute},
+	})
+	key := Key{User: "alice"}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowedCount := 0
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quota, err := limiter.Allow(key, BucketRequestsPerMinute, 1)
+			if err != nil {
+				t.Errorf("Allow returned error: %v", err)
+				return
+			}
+			if quota.Allowed {
+				mu.Lock()
+				allowedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowedCount != 50 {
+		t.Fatalf("expected exactly 50 allowed requests out of 100 concurrent callers, got %d", allowedCount)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLimiter shares quota state across replicas via a fixed-window
+// counter stored in Redis. It trades the smoothness of a true token bucket
+// for an INCRBY, an EXPIRE when a window starts, and a TTL lookup per check.
+// BucketConcurrentStreams keys get no expiry, so they count streams in
+// flight between Allow and Release.
+type RedisLimiter struct {
+	client *redis.Client
+	limits map[Bucket]Limit
+	prefix string
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string, limits []Limit) *RedisLimiter {
+	limitMap := map[Bucket]Limit{}
+	for _, limit := range limits {
+		limitMap[limit.Bucket] = limit
+	}
+
+	return &RedisLimiter{client: client, limits: limitMap, prefix: prefix}
+}
+
+func (l *RedisLimiter) redisKey(key Key, bucket Bucket) string {
+	return l.prefix + ":" + string(bucket) + ":" + key.String()
+}
+
+func (l *RedisLimiter) Allow(key Key, bucket Bucket, cost int) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	ctx := context.Background()
+	redisKey := l.redisKey(key, bucket)
+
+	count, err := l.client.IncrBy(ctx, redisKey, int64(cost)).Result()
+	if err != nil {
+		return Quota{}, err
+	}
+	if count == int64(cost) && bucket != BucketConcurrentStreams {
+		l.client.Expire(ctx, redisKey, limit.Window)
+	}
+
+	ttl, err := l.client.TTL(ctx, redisKey).Result()
+	if err != nil {
+		return Quota{}, err
+	}
+
+	quota := Quota{
+		Limit:     limit.Max,
+		Remaining: limit.Max - int(count),
+		ResetAt:   time.Now().Add(ttl),
+		Allowed:   count <= int64(limit.Max),
+	}
+	if quota.Remaining < 0 {
+		quota.Remaining = 0
+	}
+	return quota, nil
+}
+
+func (l *RedisLimiter) Peek(key Key, bucket Bucket) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	ctx := context.Background()
+	redisKey := l.redisKey(key, bucket)
+
+	count, err := l.client.Get(ctx, redisKey).Int64()
+	if err == redis.Nil {
+		return Quota{Allowed: true, Limit: limit.Max, Remaining: limit.Max}, nil
+	}
+	if err != nil {
+		return Quota{}, err
+	}
+
+	ttl, err := l.client.TTL(ctx, redisKey).Result()
+	if err != nil {
+		return Quota{}, err
+	}
+
+	remaining := limit.Max - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Quota{Allowed: count <= int64(limit.Max), Limit: limit.Max, Remaining: remaining, ResetAt: time.Now().Add(ttl)}, nil
+}
+
+func (l *RedisLimiter) Release(key Key, bucket Bucket, cost int) error {
+	if _, ok := l.limits[bucket]; !ok {
+		return nil
+	}
+
+	return l.client.DecrBy(context.Background(), l.redisKey(key, bucket), int64(cost)).Err()
+}
+
+func (l *RedisLimiter) Reset(key Key, bucket Bucket) error {
+	return l.client.Del(context.Background(), l.redisKey(key, bucket)).Err()
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// quotaRow is the persisted counter row backing SqlLimiter. It is stored in
+// the same database as the rest of casibase's data so that quotas survive
+// restarts without requiring a separate Redis deployment.
+type quotaRow struct {
+	Key        string `xorm:"varchar(200) notnull pk"`
+	Bucket     string `xorm:"varchar(50) notnull pk"`
+	Count      int    `xorm:"notnull"`
+	WindowEnds int64  `xorm:"notnull"`
+}
+
+// SqlLimiter is a Limiter backend for deployments that want quota
+// persistence but do not run Redis. It is the slowest of the three
+// backends and is best suited to low-QPS per-organization limits rather
+// than per-request hot paths.
+type SqlLimiter struct {
+	engine *xorm.Engine
+	limits map[Bucket]Limit
+}
+
+func NewSqlLimiter(engine *xorm.Engine, limits []Limit) *SqlLimiter {
+	limitMap := map[Bucket]Limit{}
+	for _, limit := range limits {
+		limitMap[limit.Bucket] = limit
+	}
+
+	return &SqlLimiter{engine: engine, limits: limitMap}
+}
+
+func (l *SqlLimiter) Allow(key Key, bucket Bucket, cost int) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	now := time.Now()
+
+	session := l.engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		return Quota{}, err
+	}
+
+	// SELECT ... FOR UPDATE so concurrent Allow calls for the same key
+	// serialize on the row instead of racing a plain Get + Insert/Update,
+	// which would otherwise let two requests both read Count=N and both
+	// write back N+cost, undercounting usage.
+	row := quotaRow{Key: key.String(), Bucket: string(bucket)}
+	existed, err := session.ForUpdate().Get(&row)
+	if err != nil {
+		session.Rollback()
+		return Quota{}, err
+	}
+
+	if !existed || row.WindowEnds <= now.Unix() {
+		row.Count = 0
+		row.WindowEnds = now.Add(limit.Window).Unix()
+	}
+	row.Count += cost
+
+	if existed {
+		_, err = session.ID(xorm.PK{row.Key, row.Bucket}).Update(&row)
+	} else {
+		_, err = session.Insert(&row)
+	}
+	if err != nil {
+		session.Rollback()
+		return Quota{}, err
+	}
+
+	err = session.Commit()
+	if err != nil {
+		return Quota{}, err
+	}
+
+	remaining := limit.Max - row.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Quota{
+		Allowed:   row.Count <= limit.Max,
+		Limit:     limit.Max,
+		Remaining: remaining,
+		ResetAt:   time.Unix(row.WindowEnds, 0),
+	}, nil
+}
+
+func (l *SqlLimiter) Peek(key Key, bucket Bucket) (Quota, error) {
+	limit, ok := l.limits[bucket]
+	if !ok {
+		return Quota{Allowed: true}, nil
+	}
+
+	row := quotaRow{Key: key.String(), Bucket: string(bucket)}
+	existed, err := l.engine.Get(&row)
+	if err != nil {
+		return Quota{}, err
+	}
+	if !existed {
+		return Quota{Allowed: true, Limit: limit.Max, Remaining: limit.Max}, nil
+	}
+
+	remaining := limit.Max - row.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Quota{Allowed: row.Count <= limit.Max, Limit: limit.Max, Remaining: remaining, ResetAt: time.Unix(row.WindowEnds, 0)}, nil
+}
+
+func (l *SqlLimiter) Release(key Key, bucket Bucket, cost int) error {
+	if _, ok := l.limits[bucket]; !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	session := l.engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		return err
+	}
+
+	row := quotaRow{Key: key.String(), Bucket: string(bucket)}
+	existed, err := session.ForUpdate().Get(&row)
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+	if !existed || row.WindowEnds <= now.Unix() {
+		// The window already rolled over (or never existed); there's
+		// nothing outstanding from this key/bucket to give back.
+		return session.Rollback()
+	}
+
+	row.Count -= cost
+	if row.Count < 0 {
+		row.Count = 0
+	}
+
+	_, err = session.ID(xorm.PK{row.Key, row.Bucket}).Update(&row)
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+func (l *SqlLimiter) Reset(key Key, bucket Bucket) error {
+	_, err := l.engine.Delete(&quotaRow{Key: key.String(), Bucket: string(bucket)})
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamstore
+
+import "sync"
+
+// Broadcaster ensures only one upstream QueryText call is in flight per
+// message ID, even if several viewers attach concurrently (a refresh, or a
+// second device opening the same chat): the first caller becomes the
+// generator and every chunk it produces is both written to the Store and
+// forwarded to every attached viewer.
+type Broadcaster struct {
+	mu       sync.Mutex
+	messages map[string]*broadcastSession
+}
+
+type broadcastSession struct {
+	mu       sync.Mutex
+	viewers  map[int]chan []byte
+	nextId   int
+	finished bool
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{messages: map[string]*broadcastSession{}}
+}
+
+// Join attaches to the broadcast session for messageId, creating it (and
+// reporting isGenerator=true) if this is the first caller. The returned
+// channel receives every chunk written via Publish from this point on;
+// earlier chunks must be fetched separately via the Store's ReplayFrom.
+// The caller must eventually call leave to release its channel.
+func (b *Broadcaster) Join(messageId string) (ch chan []byte, isGenerator bool, leave func()) {
+	b.mu.Lock()
+	session, ok := b.messages[messageId]
+	if !ok {
+		session = &broadcastSession{viewers: map[int]chan []byte{}}
+		b.messages[messageId] = session
+		isGenerator = true
+	}
+	b.mu.Unlock()
+
+	session.mu.Lock()
+	id := session.nextId
+	session.nextId++
+	ch = make(chan []byte, 64)
+	session.viewers[id] = ch
+	session.mu.Unlock()
+
+	leave = func() {
+		session.mu.Lock()
+		delete(session.viewers, id)
+		session.mu.Unlock()
+	}
+
+	return ch, isGenerator, leave
+}
+
+// Publish fans chunk out to every viewer currently attached to messageId.
+// The send does not block: a viewer whose channel buffer (64 chunks) is
+// full does not receive this chunk.
+func (b *Broadcaster) Publish(messageId string, chunk []byte) {
+	b.mu.Lock()
+	session, ok := b.messages[messageId]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	for _, viewer := range session.viewers {
+		select {
+		case viewer <- chunk:
+		default:
+		}
+	}
+}
+
+// Close marks the session for messageId finished, closes every attached
+// viewer's channel and removes the session right away.
+func (b *Broadcaster) Close(messageId string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	session, ok := b.messages[messageId]
+	if !ok {
+		return
+	}
+
+	session.mu.Lock()
+	session.finished = true
+	for _, viewer := range session.viewers {
+		close(viewer)
+	}
+	session.mu.Unlock()
+
+	delete(b.messages, messageId)
+}
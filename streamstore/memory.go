@@ -0,0 +1,118 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamstore
+
+import "sync"
+
+type memoryBuffer struct {
+	chunks      [][]byte
+	length      int
+	prunedBytes int
+	done        bool
+}
+
+// MemoryStore is the default, single-instance Store backend. maxBytes
+// bounds how much of a single answer is retained; once exceeded, the
+// oldest chunks are dropped and a client too far behind the live cursor
+// must fall back to starting the generation over.
+type MemoryStore struct {
+	mu       sync.Mutex
+	maxBytes int
+	buffers  map[string]*memoryBuffer
+}
+
+func NewMemoryStore(maxBytes int) *MemoryStore {
+	return &MemoryStore{
+		maxBytes: maxBytes,
+		buffers:  map[string]*memoryBuffer{},
+	}
+}
+
+func (s *MemoryStore) Append(messageId string, chunk []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.buffers[messageId]
+	if !ok {
+		buf = &memoryBuffer{}
+		s.buffers[messageId] = buf
+	}
+
+	buf.chunks = append(buf.chunks, append([]byte{}, chunk...))
+	buf.length += len(chunk)
+
+	for buf.length > s.maxBytes && len(buf.chunks) > 1 {
+		dropped := len(buf.chunks[0])
+		buf.length -= dropped
+		buf.prunedBytes += dropped
+		buf.chunks = buf.chunks[1:]
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) ReplayFrom(messageId string, from int) ([]byte, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.buffers[messageId]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	full := make([]byte, 0, buf.length)
+	for _, chunk := range buf.chunks {
+		full = append(full, chunk...)
+	}
+
+	skip := from - buf.prunedBytes
+	if skip < 0 {
+		skip = 0
+	}
+	if skip > len(full) {
+		skip = len(full)
+	}
+
+	return full[skip:], buf.prunedBytes + len(full), nil
+}
+
+func (s *MemoryStore) Finish(messageId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if buf, ok := s.buffers[messageId]; ok {
+		buf.done = true
+	}
+	return nil
+}
+
+func (s *MemoryStore) Done(messageId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.buffers[messageId]
+	if !ok {
+		return false, nil
+	}
+	return buf.done, nil
+}
+
+func (s *MemoryStore) Discard(messageId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buffers, messageId)
+	return nil
+}
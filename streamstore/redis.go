@@ -0,0 +1,102 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore backs the stream buffer with a Redis list, so a viewer can
+// reconnect to any replica in a multi-instance deployment and still find
+// the chunks already generated elsewhere. Unlike MemoryStore, the list is
+// not trimmed to a size bound: it keeps every chunk until ttl expires or
+// Discard is called.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client, prefix string, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl, prefix: prefix}
+}
+
+func (s *RedisStore) chunksKey(messageId string) string {
+	return s.prefix + ":chunks:" + messageId
+}
+
+func (s *RedisStore) doneKey(messageId string) string {
+	return s.prefix + ":done:" + messageId
+}
+
+func (s *RedisStore) Append(messageId string, chunk []byte) error {
+	ctx := context.Background()
+	key := s.chunksKey(messageId)
+
+	err := s.client.RPush(ctx, key, chunk).Err()
+	if err != nil {
+		return err
+	}
+
+	return s.client.Expire(ctx, key, s.ttl).Err()
+}
+
+func (s *RedisStore) ReplayFrom(messageId string, from int) ([]byte, int, error) {
+	ctx := context.Background()
+
+	chunks, err := s.client.LRange(ctx, s.chunksKey(messageId), 0, -1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var full []byte
+	for _, chunk := range chunks {
+		full = append(full, []byte(chunk)...)
+	}
+
+	skip := from
+	if skip < 0 {
+		skip = 0
+	}
+	if skip > len(full) {
+		skip = len(full)
+	}
+
+	return full[skip:], len(full), nil
+}
+
+func (s *RedisStore) Finish(messageId string) error {
+	ctx := context.Background()
+	return s.client.Set(ctx, s.doneKey(messageId), "1", s.ttl).Err()
+}
+
+func (s *RedisStore) Done(messageId string) (bool, error) {
+	ctx := context.Background()
+
+	exists, err := s.client.Exists(ctx, s.doneKey(messageId)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return exists > 0, nil
+}
+
+func (s *RedisStore) Discard(messageId string) error {
+	ctx := context.Background()
+	return s.client.Del(ctx, s.chunksKey(messageId), s.doneKey(messageId)).Err()
+}
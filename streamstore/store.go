@@ -0,0 +1,66 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package streamstore lets an in-flight AI answer survive a dropped client
+// connection: every token chunk written during generation is appended to a
+// bounded ring buffer keyed by message ID, so a client that reconnects (or a
+// second device opening the same chat) can replay what it missed before
+// attaching to the live stream.
+package streamstore
+
+import "sync"
+
+// Store is implemented by both the in-memory and Redis-backed buffers.
+type Store interface {
+	// Append adds chunk to the buffer for messageId.
+	Append(messageId string, chunk []byte) error
+
+	// ReplayFrom returns every buffered chunk after byte offset from,
+	// concatenated, plus the total number of bytes appended so far (for
+	// computing the next offset to request).
+	ReplayFrom(messageId string, from int) ([]byte, int, error)
+
+	// Finish marks the stream for messageId as complete; Done reports true
+	// for it from then on.
+	Finish(messageId string) error
+
+	// Done reports whether Finish has been called for messageId.
+	Done(messageId string) (bool, error)
+
+	// Discard drops the buffer for messageId once all viewers are caught
+	// up and the answer has been persisted to the message store.
+	Discard(messageId string) error
+}
+
+// defaultStore is the process-wide Store used by the message controller. It
+// starts as a 1 MiB MemoryStore and changes only when SetDefault is called,
+// e.g. with a RedisStore.
+var (
+	defaultStoreMu sync.RWMutex
+	defaultStore   Store = NewMemoryStore(1 << 20)
+)
+
+func SetDefault(store Store) {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+
+	defaultStore = store
+}
+
+func Default() Store {
+	defaultStoreMu.RLock()
+	defer defaultStoreMu.RUnlock()
+
+	return defaultStore
+}